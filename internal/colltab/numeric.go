@@ -0,0 +1,389 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package colltab
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// NumericOptions configures the extra numeric-sorting behavior of a Weighter
+// created with NewNumericWeighterOptions. The zero value disables all of
+// these extensions, giving the same behavior as NewNumericWeighter.
+type NumericOptions struct {
+	// Sign, if true, causes a leading ASCII ('-', U+002D) or Unicode minus
+	// (U+2212) directly followed by a digit to negate the number that
+	// follows: the primary ordering of its digit run is inverted so that
+	// negative numbers sort before zero and before positive numbers.
+	Sign bool
+
+	// DecimalSeparator, if non-zero, is the rune that separates the
+	// integer part of a number from its fractional part. A digit run
+	// following the separator is compared positionally, digit by digit,
+	// rather than folded into a single integer value, and its digits do
+	// not trigger the leading-zero handling applied to the integer part.
+	DecimalSeparator rune
+}
+
+// NewNumericWeighter wraps a weighter to recognize runs of decimal digits
+// (Unicode category Nd) and weigh them by their numeric value, so that, for
+// example, "2" sorts before "10". It is assumed, and not verified, that w
+// sorts the digits within such a run by their code point order.
+func NewNumericWeighter(w Weighter) Weighter {
+	return NewNumericWeighterOptions(w, NumericOptions{})
+}
+
+// NewNumericWeighterOptions is like NewNumericWeighter, but additionally
+// recognizes a leading sign and a decimal separator as configured by opts.
+func NewNumericWeighterOptions(w Weighter, opts NumericOptions) Weighter {
+	return &numericWeighter{w, opts}
+}
+
+type numericWeighter struct {
+	Weighter
+	opts NumericOptions
+}
+
+const (
+	// leadNumber and leadNegative mark the start of the weighed elements
+	// for a run of digits, positive or negative respectively. leadFraction
+	// marks the start of the weighed elements for the digits following a
+	// decimal separator.
+	leadNumber   = 120
+	leadNegative = 60
+	leadFraction = 130
+
+	// maxDigits bounds the weight given to the count of significant digits
+	// (and to the count of leading zeros) of a single run, so that it can
+	// never grow into the range used by leadNumber and leadFraction. A run
+	// with maxDigits or more significant digits is cut short after
+	// maxDigits-1 of them; the remaining digits are weighed as a new,
+	// independent number.
+	maxDigits = 8
+
+	// invertBase is subtracted from a primary weight to reverse its
+	// ordering for a negative number. It must be at least as large as the
+	// largest primary weight the wrapped Weighter can produce.
+	invertBase = 1<<16 - 1
+
+	minusSign = '−' // MINUS SIGN
+)
+
+func invert(p int) int { return invertBase - p }
+
+func mkElem(p int) Elem {
+	e, _ := MakeElem(p, defaultSecondary, defaultTertiary, 0)
+	return e
+}
+
+// digitVal returns the value, 0-9, of the decimal digit r. r must be in
+// unicode.Nd. Every range in unicode.Nd spans one or more complete sets of
+// ten digits, each in code point order, so the value is the offset of r
+// within its range, modulo 10.
+func digitVal(r rune) int {
+	for _, rt := range unicode.Nd.R16 {
+		if rune(rt.Lo) <= r && r <= rune(rt.Hi) {
+			return int(r-rune(rt.Lo)) % 10
+		}
+	}
+	for _, rt := range unicode.Nd.R32 {
+		if rune(rt.Lo) <= r && r <= rune(rt.Hi) {
+			return int(r-rune(rt.Lo)) % 10
+		}
+	}
+	return 0
+}
+
+func (nw *numericWeighter) scanSignBytes(b []byte) (neg bool, size int) {
+	if !nw.opts.Sign {
+		return false, 0
+	}
+	r, sz := utf8.DecodeRune(b)
+	if r != '-' && r != minusSign {
+		return false, 0
+	}
+	if r2, _ := utf8.DecodeRune(b[sz:]); !unicode.Is(unicode.Nd, r2) {
+		return false, 0
+	}
+	return true, sz
+}
+
+func (nw *numericWeighter) scanSignString(s string) (neg bool, size int) {
+	if !nw.opts.Sign {
+		return false, 0
+	}
+	r, sz := utf8.DecodeRuneInString(s)
+	if r != '-' && r != minusSign {
+		return false, 0
+	}
+	if r2, _ := utf8.DecodeRuneInString(s[sz:]); !unicode.Is(unicode.Nd, r2) {
+		return false, 0
+	}
+	return true, sz
+}
+
+// appendSegment appends the weight elements for the run of decimal digits at
+// the start of b to buf: the count of its significant digits, the weight of
+// each significant digit (as produced by the wrapped Weighter, negated if
+// neg is set), and the count of its leading zeros. It returns the updated
+// buf, the number of bytes consumed, and whether the run had to be cut short
+// because it exceeded maxDigits significant digits.
+func (nw *numericWeighter) appendSegment(buf []Elem, b []byte, neg bool) (ce []Elem, n int, truncated bool) {
+	leadingZeros, pos := 0, 0
+	for {
+		r, sz := utf8.DecodeRune(b[pos:])
+		if !unicode.Is(unicode.Nd, r) || digitVal(r) != 0 {
+			break
+		}
+		leadingZeros++
+		pos += sz
+	}
+
+	digitsStart, numDigits := pos, 0
+	for numDigits < maxDigits-1 {
+		r, sz := utf8.DecodeRune(b[pos:])
+		if !unicode.Is(unicode.Nd, r) {
+			break
+		}
+		numDigits++
+		pos += sz
+	}
+	if numDigits == maxDigits-1 {
+		if r, _ := utf8.DecodeRune(b[pos:]); unicode.Is(unicode.Nd, r) {
+			truncated = true
+		}
+	}
+	digitsEnd := pos
+
+	last := leadingZeros + 1
+	if last > maxDigits {
+		last = maxDigits
+	}
+	count := numDigits + 1
+	if neg {
+		count, last = invert(count), invert(last)
+	}
+
+	buf = append(buf, mkElem(count))
+	buf = nw.appendDigitRun(buf, b[digitsStart:digitsEnd], neg)
+	buf = append(buf, mkElem(last))
+	return buf, digitsEnd, truncated
+}
+
+func (nw *numericWeighter) appendSegmentString(buf []Elem, s string, neg bool) (ce []Elem, n int, truncated bool) {
+	leadingZeros, pos := 0, 0
+	for {
+		r, sz := utf8.DecodeRuneInString(s[pos:])
+		if !unicode.Is(unicode.Nd, r) || digitVal(r) != 0 {
+			break
+		}
+		leadingZeros++
+		pos += sz
+	}
+
+	digitsStart, numDigits := pos, 0
+	for numDigits < maxDigits-1 {
+		r, sz := utf8.DecodeRuneInString(s[pos:])
+		if !unicode.Is(unicode.Nd, r) {
+			break
+		}
+		numDigits++
+		pos += sz
+	}
+	if numDigits == maxDigits-1 {
+		if r, _ := utf8.DecodeRuneInString(s[pos:]); unicode.Is(unicode.Nd, r) {
+			truncated = true
+		}
+	}
+	digitsEnd := pos
+
+	last := leadingZeros + 1
+	if last > maxDigits {
+		last = maxDigits
+	}
+	count := numDigits + 1
+	if neg {
+		count, last = invert(count), invert(last)
+	}
+
+	buf = append(buf, mkElem(count))
+	buf = nw.appendDigitRunString(buf, s[digitsStart:digitsEnd], neg)
+	buf = append(buf, mkElem(last))
+	return buf, digitsEnd, truncated
+}
+
+// appendDigitRun appends the weight elements the wrapped Weighter assigns to
+// each digit in digits, in order, negating their primary weight if neg is
+// set. Elements with a zero primary weight are passed through unchanged, as
+// these represent non-primary differences (e.g. a varying tertiary weight
+// for a particular digit glyph) rather than part of the digit's value.
+func (nw *numericWeighter) appendDigitRun(buf []Elem, digits []byte, neg bool) []Elem {
+	for pos := 0; pos < len(digits); {
+		start := len(buf)
+		var sz int
+		buf, sz = nw.Weighter.AppendNext(buf, digits[pos:])
+		if neg {
+			invertTail(buf[start:])
+		}
+		pos += sz
+	}
+	return buf
+}
+
+func (nw *numericWeighter) appendDigitRunString(buf []Elem, digits string, neg bool) []Elem {
+	for pos := 0; pos < len(digits); {
+		start := len(buf)
+		var sz int
+		buf, sz = nw.Weighter.AppendNextString(buf, digits[pos:])
+		if neg {
+			invertTail(buf[start:])
+		}
+		pos += sz
+	}
+	return buf
+}
+
+func invertTail(es []Elem) {
+	for i, e := range es {
+		if p := e.Primary(); p != 0 {
+			ne, _ := MakeElem(invert(p), int(e.Secondary()), int(e.Tertiary()), 0)
+			es[i] = ne
+		}
+	}
+}
+
+// appendFractionBytes appends the weight elements for the fractional part
+// that starts at b, if any: the separator configured in nw.opts, followed by
+// a digit, is consumed and a lead element is appended, followed by the
+// wrapped Weighter's elements for each fractional digit in turn (negated if
+// neg is set). Unlike appendSegment, leading zeros are not treated
+// specially: each digit is compared positionally.
+func (nw *numericWeighter) appendFractionBytes(buf *[]Elem, b []byte, neg bool) (n int, ok bool) {
+	if nw.opts.DecimalSeparator == 0 {
+		return 0, false
+	}
+	r, sz := utf8.DecodeRune(b)
+	if r != nw.opts.DecimalSeparator {
+		return 0, false
+	}
+	if r2, _ := utf8.DecodeRune(b[sz:]); !unicode.Is(unicode.Nd, r2) {
+		return 0, false
+	}
+
+	lead := leadFraction
+	if neg {
+		lead = invert(lead)
+	}
+	*buf = append(*buf, mkElem(lead))
+	n = sz
+
+	for digits := 0; digits < maxDigits; digits++ {
+		r, _ := utf8.DecodeRune(b[n:])
+		if !unicode.Is(unicode.Nd, r) {
+			break
+		}
+		start := len(*buf)
+		var dsz int
+		*buf, dsz = nw.Weighter.AppendNext(*buf, b[n:])
+		if neg {
+			invertTail((*buf)[start:])
+		}
+		n += dsz
+	}
+	return n, true
+}
+
+func (nw *numericWeighter) appendFractionString(buf *[]Elem, s string, neg bool) (n int, ok bool) {
+	if nw.opts.DecimalSeparator == 0 {
+		return 0, false
+	}
+	r, sz := utf8.DecodeRuneInString(s)
+	if r != nw.opts.DecimalSeparator {
+		return 0, false
+	}
+	if r2, _ := utf8.DecodeRuneInString(s[sz:]); !unicode.Is(unicode.Nd, r2) {
+		return 0, false
+	}
+
+	lead := leadFraction
+	if neg {
+		lead = invert(lead)
+	}
+	*buf = append(*buf, mkElem(lead))
+	n = sz
+
+	for digits := 0; digits < maxDigits; digits++ {
+		r, _ := utf8.DecodeRuneInString(s[n:])
+		if !unicode.Is(unicode.Nd, r) {
+			break
+		}
+		start := len(*buf)
+		var dsz int
+		*buf, dsz = nw.Weighter.AppendNextString(*buf, s[n:])
+		if neg {
+			invertTail((*buf)[start:])
+		}
+		n += dsz
+	}
+	return n, true
+}
+
+func (nw *numericWeighter) AppendNext(buf []Elem, s []byte) (ce []Elem, n int) {
+	neg, signSize := nw.scanSignBytes(s)
+	b := s[signSize:]
+	if r, _ := utf8.DecodeRune(b); !unicode.Is(unicode.Nd, r) {
+		return nw.Weighter.AppendNext(buf, s)
+	}
+
+	lead := leadNumber
+	if neg {
+		lead = leadNegative
+	}
+	buf = append(buf, mkElem(lead))
+
+	buf, pos, truncated := nw.appendSegment(buf, b, neg)
+	if truncated {
+		return buf, signSize + pos
+	}
+
+	if sz, ok := nw.appendFractionBytes(&buf, b[pos:], neg); ok {
+		pos += sz
+	}
+	if pos >= len(b) {
+		return buf, signSize + pos
+	}
+
+	tail, sz := nw.Weighter.AppendNext(buf, b[pos:])
+	return tail, signSize + pos + sz
+}
+
+func (nw *numericWeighter) AppendNextString(buf []Elem, s string) (ce []Elem, n int) {
+	neg, signSize := nw.scanSignString(s)
+	b := s[signSize:]
+	if r, _ := utf8.DecodeRuneInString(b); !unicode.Is(unicode.Nd, r) {
+		return nw.Weighter.AppendNextString(buf, s)
+	}
+
+	lead := leadNumber
+	if neg {
+		lead = leadNegative
+	}
+	buf = append(buf, mkElem(lead))
+
+	buf, pos, truncated := nw.appendSegmentString(buf, b, neg)
+	if truncated {
+		return buf, signSize + pos
+	}
+
+	if sz, ok := nw.appendFractionString(&buf, b[pos:], neg); ok {
+		pos += sz
+	}
+	if pos >= len(b) {
+		return buf, signSize + pos
+	}
+
+	tail, sz := nw.Weighter.AppendNextString(buf, b[pos:])
+	return tail, signSize + pos + sz
+}
@@ -165,6 +165,66 @@ func TestNumericZeroOverflow(t *testing.T) {
 	}
 }
 
+func TestNumericSigned(t *testing.T) {
+	for _, tt := range []struct {
+		in string
+		w  []Elem
+	}{
+		{"1", p(120, 2, 101, 1)}, // unaffected by Sign when there is no leading minus.
+		{"-1", p(60, 65533, 65434, 65534)},
+		{"-10", p(60, 65532, 65434, 65435, 65534)},
+		{"-01c", p(60, 65533, 65434, 65533, 8, 2)},
+	} {
+		nw := NewNumericWeighterOptions(numWeighter, NumericOptions{Sign: true})
+
+		got := []Elem(nil)
+		for n, sz := 0, 0; n < len(tt.in); {
+			got, sz = nw.AppendNextString(got, tt.in[n:])
+			n += sz
+		}
+		if !reflect.DeepEqual(got, tt.w) {
+			t.Errorf("AppendNextString(%q) =\n%v; want\n%v", tt.in, got, tt.w)
+		}
+	}
+}
+
+func TestNumericFraction(t *testing.T) {
+	for _, tt := range []struct {
+		in string
+		w  []Elem
+	}{
+		{"1.5", p(120, 2, 101, 1, 130, 105)},
+		{"1.25", p(120, 2, 101, 1, 130, 102, 105)},
+	} {
+		nw := NewNumericWeighterOptions(numWeighter, NumericOptions{DecimalSeparator: '.'})
+
+		got := []Elem(nil)
+		for n, sz := 0, 0; n < len(tt.in); {
+			got, sz = nw.AppendNextString(got, tt.in[n:])
+			n += sz
+		}
+		if !reflect.DeepEqual(got, tt.w) {
+			t.Errorf("AppendNextString(%q) =\n%v; want\n%v", tt.in, got, tt.w)
+		}
+	}
+}
+
+func TestNumericFractionOverflow(t *testing.T) {
+	in := "1." + strings.Repeat("9", maxDigits+1)
+
+	nw := NewNumericWeighterOptions(numWeighter, NumericOptions{DecimalSeparator: '.'})
+
+	got, n := nw.AppendNextString(nil, in)
+	if n != len(in) {
+		t.Errorf("n: got %d; want %d", n, len(in))
+	}
+	// maxDigits fractional digits plus the one left over, merged in as
+	// trailing content.
+	if want := maxDigits + 1; len(got)-5 != want {
+		t.Errorf("digits: got %d; want %d", len(got)-5, want)
+	}
+}
+
 func TestNumericWeighterAlloc(t *testing.T) {
 	buf := make([]Elem, 100)
 	w := NewNumericWeighter(numWeighter)
@@ -0,0 +1,170 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package colltab
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// NewVersionWeighter wraps a weighter to compare dot-separated runs of
+// decimal digits, such as version numbers or chapter identifiers, segment by
+// segment as independent integers rather than as one large number or a
+// lexical string: "1.2.9" sorts before "1.2.10", and "1.9.0" before
+// "1.10.0". A number with fewer segments sorts before one that shares all of
+// its segments but has more, so "1.2" sorts before "1.2.1".
+//
+// Only a digit run followed by a '.' and another digit run is treated as a
+// version; a lone digit run falls back to the same weighing NewNumericWeighter
+// gives it. Anything following the last segment, such as the "-rc2" in
+// "v1.10.0-rc2", is weighed as it would be outside a version: digit runs in
+// it are again weighed as numbers or versions, and everything else by w.
+func NewVersionWeighter(w Weighter) Weighter {
+	return &versionWeighter{numericWeighter{w, NumericOptions{}}}
+}
+
+type versionWeighter struct {
+	numericWeighter
+}
+
+const (
+	// leadVersion marks the start of the weighed elements for a version
+	// number, distinguishing it from the lead, count, and fraction markers
+	// numericWeighter uses for a plain number.
+	leadVersion = 140
+
+	// segEnd and segMore follow each segment's weight elements, marking
+	// whether another segment follows. A version with fewer segments thus
+	// sorts before one that shares all of its segments but has more,
+	// without a difference in an earlier segment ever being overridden by
+	// a difference in the total segment count: segEnd and segMore are only
+	// compared once every element of the segment itself compares equal.
+	segEnd  = 1
+	segMore = 2
+)
+
+func countSegmentsBytes(b []byte) int {
+	segs, pos := 0, 0
+	for {
+		if r, _ := utf8.DecodeRune(b[pos:]); !unicode.Is(unicode.Nd, r) {
+			break
+		}
+		segs++
+		for {
+			r, sz := utf8.DecodeRune(b[pos:])
+			if !unicode.Is(unicode.Nd, r) {
+				break
+			}
+			pos += sz
+		}
+		r, sz := utf8.DecodeRune(b[pos:])
+		if r != '.' {
+			break
+		}
+		if r2, _ := utf8.DecodeRune(b[pos+sz:]); !unicode.Is(unicode.Nd, r2) {
+			break
+		}
+		pos += sz
+	}
+	return segs
+}
+
+func countSegmentsString(s string) int {
+	segs, pos := 0, 0
+	for {
+		if r, _ := utf8.DecodeRuneInString(s[pos:]); !unicode.Is(unicode.Nd, r) {
+			break
+		}
+		segs++
+		for {
+			r, sz := utf8.DecodeRuneInString(s[pos:])
+			if !unicode.Is(unicode.Nd, r) {
+				break
+			}
+			pos += sz
+		}
+		r, sz := utf8.DecodeRuneInString(s[pos:])
+		if r != '.' {
+			break
+		}
+		if r2, _ := utf8.DecodeRuneInString(s[pos+sz:]); !unicode.Is(unicode.Nd, r2) {
+			break
+		}
+		pos += sz
+	}
+	return segs
+}
+
+func (vw *versionWeighter) AppendNext(buf []Elem, s []byte) (ce []Elem, n int) {
+	if r, _ := utf8.DecodeRune(s); !unicode.Is(unicode.Nd, r) {
+		return vw.Weighter.AppendNext(buf, s)
+	}
+	segs := countSegmentsBytes(s)
+	if segs < 2 {
+		return vw.numericWeighter.AppendNext(buf, s)
+	}
+
+	buf = append(buf, mkElem(leadVersion))
+
+	pos := 0
+	for i := 0; i < segs; i++ {
+		var segLen int
+		var truncated bool
+		buf, segLen, truncated = vw.appendSegment(buf, s[pos:], false)
+		pos += segLen
+		if truncated {
+			return buf, pos
+		}
+		if i < segs-1 {
+			buf = append(buf, mkElem(segMore))
+			_, sz := utf8.DecodeRune(s[pos:])
+			pos += sz
+		} else {
+			buf = append(buf, mkElem(segEnd))
+		}
+	}
+
+	if pos >= len(s) {
+		return buf, pos
+	}
+	tail, sz := vw.Weighter.AppendNext(buf, s[pos:])
+	return tail, pos + sz
+}
+
+func (vw *versionWeighter) AppendNextString(buf []Elem, s string) (ce []Elem, n int) {
+	if r, _ := utf8.DecodeRuneInString(s); !unicode.Is(unicode.Nd, r) {
+		return vw.Weighter.AppendNextString(buf, s)
+	}
+	segs := countSegmentsString(s)
+	if segs < 2 {
+		return vw.numericWeighter.AppendNextString(buf, s)
+	}
+
+	buf = append(buf, mkElem(leadVersion))
+
+	pos := 0
+	for i := 0; i < segs; i++ {
+		var segLen int
+		var truncated bool
+		buf, segLen, truncated = vw.appendSegmentString(buf, s[pos:], false)
+		pos += segLen
+		if truncated {
+			return buf, pos
+		}
+		if i < segs-1 {
+			buf = append(buf, mkElem(segMore))
+			_, sz := utf8.DecodeRuneInString(s[pos:])
+			pos += sz
+		} else {
+			buf = append(buf, mkElem(segEnd))
+		}
+	}
+
+	if pos >= len(s) {
+		return buf, pos
+	}
+	tail, sz := vw.Weighter.AppendNextString(buf, s[pos:])
+	return tail, pos + sz
+}
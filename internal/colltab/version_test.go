@@ -0,0 +1,105 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package colltab
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVersionAppendNext(t *testing.T) {
+	for _, tt := range []struct {
+		in string
+		w  []Elem
+	}{
+		// A lone digit run is not a version; it falls back to the same
+		// weighing NewNumericWeighter gives it.
+		{"5", p(120, 2, 105, 1)},
+
+		{"1.2", p(140, 2, 101, 1, 2, 2, 102, 1, 1)},
+		// "10" has more significant digits than "2", so it must sort
+		// after it, regardless of the digits themselves.
+		{"1.10", p(140, 2, 101, 1, 2, 3, 101, 100, 1, 1)},
+		// Trailing, non-numeric content is weighed as usual.
+		{"1.2x", p(140, 2, 101, 1, 2, 2, 102, 1, 1, 200)},
+
+		// Mixed-script digits, as already handled by the wrapped weighter.
+		{
+			"１.２",
+			[]Elem{
+				e(140),
+				e(2), e(101, digSec, digTert+1), e(1), e(2),
+				e(2), e(102, digSec, digTert+3), e(1), e(1),
+			},
+		},
+	} {
+		vw := NewVersionWeighter(numWeighter)
+
+		b := []byte(tt.in)
+		got := []Elem(nil)
+		for n, sz := 0, 0; n < len(b); {
+			got, sz = vw.AppendNext(got, b[n:])
+			n += sz
+		}
+		if !reflect.DeepEqual(got, tt.w) {
+			t.Errorf("AppendNext(%q) =\n%v; want\n%v", tt.in, got, tt.w)
+		}
+
+		got = nil
+		for n, sz := 0, 0; n < len(tt.in); {
+			got, sz = vw.AppendNextString(got, tt.in[n:])
+			n += sz
+		}
+		if !reflect.DeepEqual(got, tt.w) {
+			t.Errorf("AppendNextString(%q) =\n%v; want\n%v", tt.in, got, tt.w)
+		}
+	}
+}
+
+// TestVersionSegmentDominance verifies that a difference in an earlier
+// segment always determines the order, even when it leaves the two version
+// strings with a different number of segments: "1.3.5" sorts before "1.20"
+// because its second segment, 3, is less than 20, regardless of "1.3.5"
+// having a third segment that "1.20" lacks.
+func TestVersionSegmentDominance(t *testing.T) {
+	vw := NewVersionWeighter(numWeighter)
+
+	weigh := func(s string) []Elem {
+		got := []Elem(nil)
+		for n, sz := 0, 0; n < len(s); {
+			got, sz = vw.AppendNextString(got, s[n:])
+			n += sz
+		}
+		return got
+	}
+
+	a := weigh("1.3.5")
+	wantA := p(140, 2, 101, 1, 2, 2, 103, 1, 2, 2, 105, 1, 1)
+	if !reflect.DeepEqual(a, wantA) {
+		t.Errorf("AppendNextString(%q) =\n%v; want\n%v", "1.3.5", a, wantA)
+	}
+
+	b := weigh("1.20")
+	wantB := p(140, 2, 101, 1, 2, 3, 102, 100, 1, 1)
+	if !reflect.DeepEqual(b, wantB) {
+		t.Errorf("AppendNextString(%q) =\n%v; want\n%v", "1.20", b, wantB)
+	}
+
+	if !primariesLess(a, b) {
+		t.Errorf("primary weights of weigh(%q) do not sort before weigh(%q)", "1.3.5", "1.20")
+	}
+}
+
+// primariesLess reports whether a sorts before b when compared element by
+// element on primary weight alone, the way a primary-level string
+// comparison would.
+func primariesLess(a, b []Elem) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if p, q := a[i].Primary(), b[i].Primary(); p != q {
+			return p < q
+		}
+	}
+	return len(a) < len(b)
+}